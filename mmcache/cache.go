@@ -0,0 +1,195 @@
+// Package mmcache provides a disk-backed, content-addressable cache for
+// multimodal (vision projector) embeddings, so that repeated images in a
+// chat session or RAG pipeline can skip re-running ImageProcessor and
+// VisionModel.
+package mmcache
+
+import (
+	"container/list"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// Config controls the cache's disk footprint and eviction policy.
+type Config struct {
+	// Dir is where cache entries are persisted. An empty Dir disables
+	// the cache.
+	Dir string
+
+	// MaxBytes is the approximate total size of cache entries kept on
+	// disk before the least-recently-used ones are evicted.
+	MaxBytes int64
+}
+
+// Cache is an LRU cache of float32 embeddings keyed by a strong content
+// hash (gemma3 uses the FNV64a hash of each image tile's pixel bytes),
+// backed by files under Config.Dir so entries survive process restarts.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[uint64]*list.Element
+	size  int64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type entry struct {
+	key  uint64
+	size int64
+}
+
+// New returns a Cache rooted at cfg.Dir, or nil if the cache is disabled
+// (cfg.Dir is empty).
+func New(cfg Config) (*Cache, error) {
+	if cfg.Dir == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &Cache{
+		dir:      cfg.Dir,
+		maxBytes: cfg.MaxBytes,
+		lru:      list.New(),
+		items:    make(map[uint64]*list.Element),
+	}
+
+	// Index entries left on disk by an earlier process.
+	files, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		var key uint64
+		info, err := f.Info()
+		if err != nil || f.IsDir() {
+			continue
+		}
+		if _, err := fmt.Sscanf(f.Name(), "%016x.bin", &key); err != nil {
+			continue
+		}
+		c.items[key] = c.lru.PushBack(&entry{key: key, size: info.Size()})
+		c.size += info.Size()
+	}
+	c.evict()
+
+	return c, nil
+}
+
+func (c *Cache) path(key uint64) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%016x.bin", key))
+}
+
+// Get returns the cached embedding for key, if present, promoting it to
+// most-recently-used.
+func (c *Cache) Get(key uint64) ([]float32, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	el, ok := c.items[key]
+	if ok {
+		c.lru.MoveToFront(el)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return decodeFloats(data), true
+}
+
+// Put stores embedding under key, evicting least-recently-used entries
+// until the cache fits within MaxBytes.
+func (c *Cache) Put(key uint64, embedding []float32) error {
+	if c == nil {
+		return nil
+	}
+
+	data := encodeFloats(embedding)
+	if err := os.WriteFile(c.path(key), data, 0o644); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.lru.MoveToFront(el)
+		c.size += int64(len(data)) - el.Value.(*entry).size
+		el.Value.(*entry).size = int64(len(data))
+	} else {
+		el := c.lru.PushFront(&entry{key: key, size: int64(len(data))})
+		c.items[key] = el
+		c.size += int64(len(data))
+	}
+
+	c.evict()
+	return nil
+}
+
+func (c *Cache) evict() {
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		back := c.lru.Back()
+		if back == nil {
+			return
+		}
+
+		ent := back.Value.(*entry)
+		os.Remove(c.path(ent.key))
+		c.size -= ent.size
+		delete(c.items, ent.key)
+		c.lru.Remove(back)
+	}
+}
+
+// Metrics reports cumulative hit/miss counts since the cache was created.
+type Metrics struct {
+	Hits   uint64
+	Misses uint64
+}
+
+func (c *Cache) Metrics() Metrics {
+	if c == nil {
+		return Metrics{}
+	}
+
+	return Metrics{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+func encodeFloats(f32s []float32) []byte {
+	buf := make([]byte, len(f32s)*4)
+	for i, f := range f32s {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeFloats(data []byte) []float32 {
+	f32s := make([]float32, len(data)/4)
+	for i := range f32s {
+		f32s[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return f32s
+}
@@ -0,0 +1,100 @@
+package mmcache
+
+import "testing"
+
+func TestCacheDisabledWithEmptyDir(t *testing.T) {
+	c, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c != nil {
+		t.Fatal("expected a nil cache when Dir is empty")
+	}
+
+	// A nil *Cache must be safe to use so callers don't need to special-case
+	// a disabled cache at every call site.
+	if _, ok := c.Get(1); ok {
+		t.Fatal("Get on a nil cache should always miss")
+	}
+	if err := c.Put(1, []float32{1, 2, 3}); err != nil {
+		t.Fatalf("Put on a nil cache should be a no-op, got error: %v", err)
+	}
+}
+
+func TestCachePutGetRoundTrip(t *testing.T) {
+	c, err := New(Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	want := []float32{1.5, -2.25, 3}
+	if err := c.Put(42, want); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok := c.Get(42)
+	if !ok {
+		t.Fatal("expected a hit for a key that was just stored")
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d floats, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("float %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if m := c.Metrics(); m.Hits != 1 || m.Misses != 0 {
+		t.Errorf("Metrics = %+v, want 1 hit and 0 misses", m)
+	}
+}
+
+func TestCacheMiss(t *testing.T) {
+	c, err := New(Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, ok := c.Get(99); ok {
+		t.Fatal("expected a miss for a key that was never stored")
+	}
+	if m := c.Metrics(); m.Misses != 1 {
+		t.Errorf("Metrics = %+v, want 1 miss", m)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	// Each entry is 4 floats = 16 bytes; cap the cache to fit two.
+	c, err := New(Config{Dir: t.TempDir(), MaxBytes: 32})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	entry := []float32{1, 2, 3, 4}
+	if err := c.Put(1, entry); err != nil {
+		t.Fatalf("Put(1): %v", err)
+	}
+	if err := c.Put(2, entry); err != nil {
+		t.Fatalf("Put(2): %v", err)
+	}
+
+	// Touch key 1 so key 2 becomes the least-recently-used entry.
+	if _, ok := c.Get(1); !ok {
+		t.Fatal("expected key 1 to still be cached")
+	}
+
+	if err := c.Put(3, entry); err != nil {
+		t.Fatalf("Put(3): %v", err)
+	}
+
+	if _, ok := c.Get(2); ok {
+		t.Fatal("expected key 2 to have been evicted as least-recently-used")
+	}
+	if _, ok := c.Get(1); !ok {
+		t.Fatal("expected key 1 to survive eviction since it was touched most recently")
+	}
+	if _, ok := c.Get(3); !ok {
+		t.Fatal("expected the just-inserted key 3 to be cached")
+	}
+}
@@ -0,0 +1,60 @@
+package mmcache
+
+import "hash/fnv"
+
+// windowSize is the number of trailing bytes considered by the rolling sum
+// when deciding whether the current position is a chunk boundary.
+const windowSize = 64
+
+// chunkMask is the boundary condition: a boundary is declared wherever the
+// rolling sum's low 13 bits are all zero. A sum of windowSize bytes is at
+// most 16320, so that means a window sum of exactly 0 or 8192; chunk sizes
+// therefore depend on the byte distribution rather than averaging mask+1
+// bytes, and data whose windows never sum to 8192 yields a single chunk.
+const chunkMask = 1<<13 - 1
+
+// Chunk splits data into content-defined chunks using a windowed rolling
+// sum over the raw bytes: a boundary falls wherever the sum of the last
+// windowSize bytes has all of its chunkMask bits zero. Because the
+// boundary only depends on local content, near-duplicate inputs that
+// share byte regions produce many identical chunks. The gemma3 tile cache
+// does not use Chunk: it keys entries by a hash of each whole tile, so no
+// projector output is stored or reused per chunk.
+func Chunk(data []byte) [][]byte {
+	if len(data) <= windowSize {
+		return [][]byte{data}
+	}
+
+	var chunks [][]byte
+	start := 0
+	var sum uint32
+
+	for i, b := range data {
+		sum += uint32(b)
+		if i >= windowSize {
+			sum -= uint32(data[i-windowSize])
+		}
+
+		atBoundary := i >= windowSize && sum&chunkMask == 0
+		if atBoundary && i+1-start >= windowSize {
+			chunks = append(chunks, data[start:i+1])
+			start = i + 1
+		}
+	}
+
+	if start < len(data) {
+		chunks = append(chunks, data[start:])
+	}
+
+	return chunks
+}
+
+// StrongHash computes the content hash used as a chunk's cache key. It is
+// distinct from the rolling sum used to find boundaries: the rolling sum
+// only needs to be cheap, while this needs to be collision-resistant
+// enough to key a cache.
+func StrongHash(b []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
@@ -0,0 +1,66 @@
+package mmcache
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestChunkReassemblesToOriginal(t *testing.T) {
+	data := make([]byte, 10_000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	var got []byte
+	for _, c := range Chunk(data) {
+		got = append(got, c...)
+	}
+
+	if !bytes.Equal(got, data) {
+		t.Fatal("concatenated chunks do not reassemble the original data")
+	}
+}
+
+func TestChunkSharedPrefixSharesLeadingChunks(t *testing.T) {
+	// Pseudo-random bytes give the rolling sum boundaries to find; a short
+	// periodic pattern such as byte(i*13) never sums to a boundary value
+	// and comes back as one chunk.
+	base := make([]byte, 10_000)
+	rand.New(rand.NewSource(1)).Read(base)
+
+	// b differs only in its tail, so the two inputs share a 9000-byte
+	// leading region.
+	b := append([]byte(nil), base...)
+	for i := 9000; i < len(b); i++ {
+		b[i] ^= 0xff
+	}
+
+	chunksA := Chunk(base)
+	chunksB := Chunk(b)
+
+	var shared int
+	for i := 0; i < len(chunksA) && i < len(chunksB); i++ {
+		if !bytes.Equal(chunksA[i], chunksB[i]) {
+			break
+		}
+		shared++
+	}
+
+	if shared == 0 {
+		t.Fatal("expected at least one leading chunk to be shared between inputs with a common prefix")
+	}
+}
+
+func TestStrongHashDeterministic(t *testing.T) {
+	data := []byte("some tile bytes")
+	if StrongHash(data) != StrongHash(append([]byte(nil), data...)) {
+		t.Fatal("StrongHash is not deterministic for equal content")
+	}
+}
+
+func TestStrongHashDiffersOnDifferentContent(t *testing.T) {
+	if StrongHash([]byte("a")) == StrongHash([]byte("b")) {
+		t.Fatal("expected different content to hash differently")
+	}
+}
@@ -0,0 +1,94 @@
+package model
+
+import "sync"
+
+// Event is a single pipeline stage notification published on an Events bus.
+// RequestID correlates the stages of one request: Events is shared by every
+// concurrent request a Model serves, so a subscriber needs it to tell, say,
+// which in-flight prompt's image just finished vision_forward_done.
+type Event struct {
+	Topic     string
+	RequestID string
+	Data      any
+}
+
+// Pipeline stage topics published around the multimodal encode/decode path.
+// Consumers such as profiling, tracing, or "reading image..." progress UIs
+// subscribe to these via Events.Listen without needing to modify the model.
+const (
+	EventImageDecoded      = "image_decoded"
+	EventImagePreprocessed = "image_preprocessed"
+	EventVisionForwardDone = "vision_forward_done"
+	EventProjectorDone     = "projector_done"
+	EventTokensExpanded    = "tokens_expanded"
+	EventTextForwardStep   = "text_forward_step"
+)
+
+// listenerBuffer is the channel capacity given to each subscriber so a slow
+// consumer cannot block the model's forward pass; publishes to a full
+// channel are dropped rather than blocking.
+const listenerBuffer = 64
+
+// Events is a channel-based pub/sub bus for observing a model's pipeline
+// stages from the outside: timings, tensor shapes, and hashes, without
+// modifying the model itself.
+type Events struct {
+	mu          sync.RWMutex
+	subscribers map[string][]chan Event
+}
+
+// NewEvents returns an empty Events bus.
+func NewEvents() *Events {
+	return &Events{subscribers: make(map[string][]chan Event)}
+}
+
+// Listen returns a channel that receives every Event published to topic
+// from this point on, and a cancel func that unsubscribes it and closes
+// the channel. Callers - such as a per-request "reading image..." progress
+// UI - must call cancel once they stop reading, or the subscription and
+// its channel leak for the life of the process.
+func (e *Events) Listen(topic string) (<-chan Event, func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan Event, listenerBuffer)
+	e.subscribers[topic] = append(e.subscribers[topic], ch)
+
+	cancel := func() { e.unlisten(topic, ch) }
+	return ch, cancel
+}
+
+// unlisten removes ch from topic's subscriber list and closes it.
+func (e *Events) unlisten(topic string, ch chan Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	subs := e.subscribers[topic]
+	for i, sub := range subs {
+		if sub == ch {
+			e.subscribers[topic] = append(subs[:i], subs[i+1:]...)
+			close(ch)
+			break
+		}
+	}
+}
+
+// Publish sends data, tagged with requestID, to every subscriber of topic.
+// It is a no-op if e is nil, so models can publish unconditionally even
+// when no bus was wired up.
+func (e *Events) Publish(requestID, topic string, data any) {
+	if e == nil {
+		return
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	event := Event{Topic: topic, RequestID: requestID, Data: data}
+	for _, ch := range e.subscribers[topic] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package model
+
+import "testing"
+
+func TestEventsPublishDeliversToSubscriber(t *testing.T) {
+	e := NewEvents()
+	ch, cancel := e.Listen(EventImageDecoded)
+	defer cancel()
+
+	e.Publish("req-1", EventImageDecoded, "payload")
+
+	select {
+	case ev := <-ch:
+		if ev.Topic != EventImageDecoded || ev.RequestID != "req-1" || ev.Data != "payload" {
+			t.Errorf("got %+v, want topic=%s requestID=req-1 data=payload", ev, EventImageDecoded)
+		}
+	default:
+		t.Fatal("expected an event to be buffered for the subscriber")
+	}
+}
+
+func TestEventsPublishOnlyReachesMatchingTopic(t *testing.T) {
+	e := NewEvents()
+	ch, cancel := e.Listen(EventImageDecoded)
+	defer cancel()
+
+	e.Publish("req-1", EventProjectorDone, "other")
+
+	select {
+	case ev := <-ch:
+		t.Fatalf("did not expect an event on a different topic, got %+v", ev)
+	default:
+	}
+}
+
+func TestEventsCancelStopsDeliveryAndClosesChannel(t *testing.T) {
+	e := NewEvents()
+	ch, cancel := e.Listen(EventImageDecoded)
+	cancel()
+
+	e.Publish("req-1", EventImageDecoded, "payload")
+
+	if _, open := <-ch; open {
+		t.Fatal("expected the channel to be closed after cancel")
+	}
+}
+
+func TestEventsPublishOnNilBusIsNoop(t *testing.T) {
+	var e *Events
+	e.Publish("req-1", EventImageDecoded, "payload") // must not panic
+}
+
+func TestEventsRequestIDDistinguishesConcurrentRequests(t *testing.T) {
+	e := NewEvents()
+	ch, cancel := e.Listen(EventVisionForwardDone)
+	defer cancel()
+
+	e.Publish("req-1", EventVisionForwardDone, "a")
+	e.Publish("req-2", EventVisionForwardDone, "b")
+
+	seen := map[string]any{}
+	for i := 0; i < 2; i++ {
+		ev := <-ch
+		seen[ev.RequestID] = ev.Data
+	}
+
+	if seen["req-1"] != "a" || seen["req-2"] != "b" {
+		t.Fatalf("expected events tagged with distinct request ids, got %+v", seen)
+	}
+}
@@ -0,0 +1,54 @@
+package gemma3
+
+import (
+	"hash/fnv"
+	"image"
+
+	"github.com/ollama/ollama/ml"
+	"github.com/ollama/ollama/mmcache"
+)
+
+func newMultimodalCache(c ml.Config) (*mmcache.Cache, error) {
+	return mmcache.New(mmcache.Config{
+		Dir:      c.String("cache.multimodal.dir", ""),
+		MaxBytes: int64(c.Uint("cache.multimodal.max_bytes", 0)),
+	})
+}
+
+// tileCacheKey hashes a tile's raw RGBA pixel bytes as a whole, so an exact
+// repeat of a tile (very common across turns of the same chat session, or
+// across requests in a RAG pipeline that resends the same attachment) hits
+// the cache.
+//
+// A per-chunk scheme was tried here and reverted: tileBytes flattens pixels
+// row-major within a single tile's own width, so the visual band two
+// side-by-side Pan & Scan crops share does not appear as a contiguous run of
+// bytes in either tile's buffer - it's interleaved once per row with each
+// tile's own unique remainder. A content-defined chunker's boundaries
+// therefore almost never line up between two side-by-side overlapping
+// tiles (only crops in the same column share whole rows), so the chunks
+// rarely reuse projector output across tiles,
+// while adding real risk: slicing the projected tokens by byte-offset
+// share and caching each slice under its chunk hash has no actual
+// correspondence to which tokens the vision tower produced from that
+// region, and a same-length hash collision between two unrelated low
+// entropy regions (a flat background, letterboxing) would splice one
+// image's tokens into another's. Whole-tile hashing below doesn't have
+// either problem.
+func tileCacheKey(tile image.Image) uint64 {
+	h := fnv.New64a()
+	h.Write(tileBytes(tile))
+	return h.Sum64()
+}
+
+func tileBytes(tile image.Image) []byte {
+	bounds := tile.Bounds()
+	buf := make([]byte, 0, bounds.Dx()*bounds.Dy()*4)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := tile.At(x, y).RGBA()
+			buf = append(buf, byte(r>>8), byte(g>>8), byte(b>>8), byte(a>>8))
+		}
+	}
+	return buf
+}
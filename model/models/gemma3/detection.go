@@ -0,0 +1,216 @@
+package gemma3
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/ollama/ollama/ml"
+	"github.com/ollama/ollama/ml/nn"
+)
+
+// Anchor is a single anchor box prior, in pixels, that DetectionHead
+// decodes its width/height predictions relative to.
+type Anchor struct {
+	Width  float32
+	Height float32
+}
+
+// Box is a single detection produced by Model.Detect, in pixel coordinates
+// of the original (undecoded-resolution) input image.
+type Box struct {
+	X, Y, W, H float32
+	Confidence float32
+	ClassID    int
+	ClassProb  float32
+}
+
+// DetectionHead is a YOLO-style anchor-based decoder that runs on top of
+// the vision tower's patch grid, predicting [x, y, w, h, obj, class_logits...]
+// per anchor per grid cell.
+type DetectionHead struct {
+	Predict *nn.Linear `gguf:"detect.predict"`
+
+	anchors    []Anchor
+	numClasses int
+}
+
+func newDetectionHead(c ml.Config) *DetectionHead {
+	anchorDims := c.Floats("detection.anchors")
+	anchors := make([]Anchor, 0, len(anchorDims)/2)
+	for i := 0; i+1 < len(anchorDims); i += 2 {
+		anchors = append(anchors, Anchor{Width: anchorDims[i], Height: anchorDims[i+1]})
+	}
+
+	return &DetectionHead{
+		anchors:    anchors,
+		numClasses: int(c.Uint("detection.num_classes")),
+	}
+}
+
+// predictionsPerAnchor is the length of each anchor's raw prediction
+// vector: tx, ty, tw, th, objectness, then one logit per class.
+func (d *DetectionHead) predictionsPerAnchor() int {
+	return 5 + d.numClasses
+}
+
+// Forward predicts raw, un-decoded anchor outputs for every cell of the
+// patchesPerImage x patchesPerImage grid. The returned tensor has shape
+// (predictionsPerAnchor * len(anchors), patchesPerImage*patchesPerImage).
+func (d *DetectionHead) Forward(ctx ml.Context, visionOutputs ml.Tensor) ml.Tensor {
+	return d.Predict.Forward(ctx, visionOutputs)
+}
+
+// decode turns raw per-cell, per-anchor predictions into image-space boxes,
+// applying sigmoid to objectness/xy and anchor-scaled exp to wh, mirroring
+// the Darknet YOLO layer.
+func (d *DetectionHead) decode(raw []float32, gridSize, imageSize int, classThreshold float32) []Box {
+	stride := float32(imageSize) / float32(gridSize)
+	perAnchor := d.predictionsPerAnchor()
+
+	var boxes []Box
+	for cell := 0; cell < gridSize*gridSize; cell++ {
+		cy, cx := cell/gridSize, cell%gridSize
+
+		for a, anchor := range d.anchors {
+			base := cell*len(d.anchors)*perAnchor + a*perAnchor
+			pred := raw[base : base+perAnchor]
+
+			objectness := sigmoid(pred[4])
+			if objectness < classThreshold {
+				continue
+			}
+
+			classID, classProb := argmaxSigmoid(pred[5:])
+			if objectness*classProb < classThreshold {
+				continue
+			}
+
+			x := (float32(cx) + sigmoid(pred[0])) * stride
+			y := (float32(cy) + sigmoid(pred[1])) * stride
+			w := anchor.Width * exp(pred[2])
+			h := anchor.Height * exp(pred[3])
+
+			boxes = append(boxes, Box{
+				X: x, Y: y, W: w, H: h,
+				Confidence: objectness,
+				ClassID:    classID,
+				ClassProb:  classProb,
+			})
+		}
+	}
+
+	return boxes
+}
+
+// Detect runs the vision tower followed by the detection head over image,
+// returning boxes surviving non-maximum suppression at iouThreshold. It
+// returns an error if the loaded model has no detect.* weights, since
+// DetectionHead.Predict is only populated for a fine-tuned Gemma3-detect
+// checkpoint.
+func (m *Model) Detect(ctx ml.Context, imageData []byte, classThreshold, iouThreshold float32) ([]Box, error) {
+	if m.DetectionHead == nil || m.DetectionHead.Predict == nil {
+		return nil, fmt.Errorf("gemma3: Detect requires a checkpoint with detect.* weights loaded")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, err
+	}
+
+	f32s, err := m.ImageProcessor.ProcessImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	pixelValues, err := ctx.Input().FromFloatSlice(f32s,
+		m.ImageProcessor.imageSize,
+		m.ImageProcessor.imageSize,
+		m.ImageProcessor.numChannels,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	visionOutputs := m.VisionModel.Forward(ctx, pixelValues)
+	raw := m.DetectionHead.Forward(ctx, visionOutputs).Floats()
+
+	patchesPerImage := m.ImageProcessor.imageSize / m.ImageProcessor.patchSize
+	boxes := m.DetectionHead.decode(raw, patchesPerImage, m.ImageProcessor.imageSize, classThreshold)
+
+	// decode works in the imageSize x imageSize frame the vision tower sees;
+	// scale boxes back to the original image's pixel coordinates.
+	bounds := img.Bounds()
+	sx := float32(bounds.Dx()) / float32(m.ImageProcessor.imageSize)
+	sy := float32(bounds.Dy()) / float32(m.ImageProcessor.imageSize)
+	for i := range boxes {
+		boxes[i].X = float32(bounds.Min.X) + boxes[i].X*sx
+		boxes[i].Y = float32(bounds.Min.Y) + boxes[i].Y*sy
+		boxes[i].W *= sx
+		boxes[i].H *= sy
+	}
+
+	return nms(boxes, iouThreshold), nil
+}
+
+func sigmoid(x float32) float32 {
+	return 1 / (1 + exp(-x))
+}
+
+func exp(x float32) float32 {
+	return float32(math.Exp(float64(x)))
+}
+
+func argmaxSigmoid(logits []float32) (int, float32) {
+	best, bestProb := 0, float32(0)
+	for i, l := range logits {
+		if p := sigmoid(l); p > bestProb {
+			best, bestProb = i, p
+		}
+	}
+	return best, bestProb
+}
+
+// nms greedily keeps the highest-confidence box in each cluster of
+// same-class boxes whose IoU exceeds iouThreshold, discarding the rest.
+// Boxes of different classes never suppress each other.
+func nms(boxes []Box, iouThreshold float32) []Box {
+	sort.Slice(boxes, func(i, j int) bool {
+		return boxes[i].Confidence*boxes[i].ClassProb > boxes[j].Confidence*boxes[j].ClassProb
+	})
+
+	var kept []Box
+	for _, b := range boxes {
+		keep := true
+		for _, k := range kept {
+			if k.ClassID == b.ClassID && iou(b, k) > iouThreshold {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			kept = append(kept, b)
+		}
+	}
+
+	return kept
+}
+
+func iou(a, b Box) float32 {
+	ax0, ay0, ax1, ay1 := a.X-a.W/2, a.Y-a.H/2, a.X+a.W/2, a.Y+a.H/2
+	bx0, by0, bx1, by1 := b.X-b.W/2, b.Y-b.H/2, b.X+b.W/2, b.Y+b.H/2
+
+	ix0, iy0 := max(ax0, bx0), max(ay0, by0)
+	ix1, iy1 := min(ax1, bx1), min(ay1, by1)
+
+	iw, ih := max(0, ix1-ix0), max(0, iy1-iy0)
+	intersection := iw * ih
+	union := a.W*a.H + b.W*b.H - intersection
+	if union <= 0 {
+		return 0
+	}
+
+	return intersection / union
+}
@@ -0,0 +1,57 @@
+package gemma3
+
+import "testing"
+
+func TestIoU(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b Box
+		want float32
+	}{
+		{"identical", Box{X: 10, Y: 10, W: 10, H: 10}, Box{X: 10, Y: 10, W: 10, H: 10}, 1},
+		{"disjoint", Box{X: 0, Y: 0, W: 10, H: 10}, Box{X: 100, Y: 100, W: 10, H: 10}, 0},
+		{"half overlap", Box{X: 0, Y: 0, W: 10, H: 10}, Box{X: 5, Y: 0, W: 10, H: 10}, 1.0 / 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := iou(c.a, c.b); abs(got-c.want) > 1e-4 {
+				t.Errorf("iou(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+			}
+		})
+	}
+}
+
+func TestNMSDropsOverlappingLowerConfidence(t *testing.T) {
+	boxes := []Box{
+		{X: 10, Y: 10, W: 10, H: 10, Confidence: 0.9, ClassProb: 1, ClassID: 0},
+		{X: 11, Y: 11, W: 10, H: 10, Confidence: 0.6, ClassProb: 1, ClassID: 0},
+		{X: 100, Y: 100, W: 10, H: 10, Confidence: 0.8, ClassProb: 1, ClassID: 0},
+	}
+
+	kept := nms(boxes, 0.5)
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 boxes to survive NMS, got %d", len(kept))
+	}
+	if kept[0].Confidence != 0.9 || kept[1].Confidence != 0.8 {
+		t.Errorf("expected the highest-confidence box in each cluster to survive, got %+v", kept)
+	}
+}
+
+func TestNMSKeepsDistinctClasses(t *testing.T) {
+	boxes := []Box{
+		{X: 10, Y: 10, W: 10, H: 10, Confidence: 0.9, ClassProb: 1, ClassID: 0},
+		{X: 10, Y: 10, W: 10, H: 10, Confidence: 0.9, ClassProb: 1, ClassID: 1},
+	}
+
+	if kept := nms(boxes, 0.5); len(kept) != 2 {
+		t.Fatalf("expected identical boxes of different classes to both survive, got %d", len(kept))
+	}
+}
+
+func abs(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
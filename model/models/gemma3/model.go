@@ -3,6 +3,7 @@ package gemma3
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"hash/fnv"
 	"image"
 	"slices"
@@ -10,6 +11,7 @@ import (
 	"github.com/ollama/ollama/kvcache"
 	"github.com/ollama/ollama/ml"
 	"github.com/ollama/ollama/ml/nn"
+	"github.com/ollama/ollama/mmcache"
 	"github.com/ollama/ollama/model"
 	"github.com/ollama/ollama/model/input"
 )
@@ -22,8 +24,14 @@ type Model struct {
 	*TextModel
 
 	*MultiModalProjector `gguf:"mm"`
+	*DetectionHead       `gguf:"detect"`
 
 	ImageProcessor
+
+	textEmbeddingLength int
+	mmCache             *mmcache.Cache
+
+	Events *model.Events
 }
 
 var _ model.MultimodalProcessor = (*Model)(nil)
@@ -57,24 +65,63 @@ func New(c ml.Config) (model.Model, error) {
 				AddEOT: c.Bool("tokenizer.ggml.add_eot_token", false),
 			},
 		),
-		ImageProcessor: newImageProcessor(c),
-		VisionModel:    newVisionModel(c),
-		TextModel:      newTextModel(c),
+		ImageProcessor:      newImageProcessor(c),
+		VisionModel:         newVisionModel(c),
+		TextModel:           newTextModel(c),
+		DetectionHead:       newDetectionHead(c),
+		textEmbeddingLength: int(c.Uint("text.embedding_length")),
+		Events:              model.NewEvents(),
 	}
 
 	slidingWindowLen := int32(c.Uint("text.attention.sliding_window"))
 	m.Cache = kvcache.NewWrapperCache(kvcache.NewSWACache(slidingWindowLen, m.Shift), kvcache.NewCausalCache(m.Shift))
 
+	mmCache, err := newMultimodalCache(c)
+	if err != nil {
+		return nil, err
+	}
+	m.mmCache = mmCache
+
 	return &m, nil
 }
 
-func (m *Model) EncodeMultimodal(ctx ml.Context, multimodalData []byte) (any, error) {
-	image, _, err := image.Decode(bytes.NewReader(multimodalData))
+// requestID derives a correlation id for the events published while
+// handling one EncodeMultimodal/PostTokenize/Forward call. The runner hands
+// each request its own ml.Context, so formatting the Context's pointer gives
+// every event published while serving the same request a shared id, which
+// is how a subscriber tells apart the image_decoded, vision_forward_done,
+// etc. of one in-flight request from another's on the same Events bus.
+func requestID(ctx ml.Context) string {
+	return fmt.Sprintf("%p", ctx)
+}
+
+// encodeTile runs the vision tower and projector over a single image tile,
+// reducing its patchesPerImage x patchesPerImage grid to 16x16 = 256 soft
+// tokens via 2D adaptive average pooling along both spatial axes. Results
+// are cached in m.mmCache keyed by the tile's whole-buffer hash, so an
+// exact repeat of a tile - the common case of a chat session or RAG
+// pipeline resending the same attachment - skips the vision tower and
+// projector.
+func (m *Model) encodeTile(ctx ml.Context, reqID string, tile image.Image) (ml.Tensor, error) {
+	key := tileCacheKey(tile)
+	if cached, ok := m.mmCache.Get(key); ok {
+		return ctx.Input().FromFloatSlice(cached, m.textEmbeddingLength, 256)
+	}
+
+	visionOutputs, err := m.encodeTileUncached(ctx, reqID, tile)
 	if err != nil {
 		return nil, err
 	}
 
-	f32s, err := m.ImageProcessor.ProcessImage(image)
+	if err := m.mmCache.Put(key, visionOutputs.Floats()); err != nil {
+		return nil, err
+	}
+
+	return visionOutputs, nil
+}
+
+func (m *Model) encodeTileUncached(ctx ml.Context, reqID string, tile image.Image) (ml.Tensor, error) {
+	f32s, err := m.ImageProcessor.ProcessImage(tile)
 	if err != nil {
 		return nil, err
 	}
@@ -88,18 +135,89 @@ func (m *Model) EncodeMultimodal(ctx ml.Context, multimodalData []byte) (any, er
 		return nil, err
 	}
 
+	m.Events.Publish(reqID, model.EventImagePreprocessed, tile.Bounds())
+
 	visionOutputs := m.VisionModel.Forward(ctx, pixelValues)
+	m.Events.Publish(reqID, model.EventVisionForwardDone, visionOutputs.Shape())
+
+	// visionOutputs is (hidden_dim, num_patches, batch); bring hidden_dim
+	// out of dim0 so the patch grid can be reshaped into its 2D layout.
 	visionOutputs = visionOutputs.Permute(ctx, 1, 0, 2, 3).Contiguous(ctx)
 	patchesPerImage := m.ImageProcessor.imageSize / m.ImageProcessor.patchSize
-	kernelSize := patchesPerImage * patchesPerImage / 256
-	visionOutputs = visionOutputs.AvgPool1D(ctx, kernelSize, kernelSize, 0)
+	hiddenDim, batch := visionOutputs.Dim(1), visionOutputs.Dim(2)
+	visionOutputs = visionOutputs.Reshape(ctx, patchesPerImage, patchesPerImage, hiddenDim, batch)
+	visionOutputs = visionOutputs.AvgPool2D(ctx, patchesPerImage/16, patchesPerImage/16, 0)
+	visionOutputs = visionOutputs.Reshape(ctx, 256, hiddenDim, batch)
 
 	visionOutputs = visionOutputs.Permute(ctx, 1, 0, 2, 3).Contiguous(ctx)
 	visionOutputs = m.MultiModalProjector.Forward(ctx, visionOutputs, m.VisionModel.eps)
+	m.Events.Publish(reqID, model.EventProjectorDone, visionOutputs.Shape())
+	return visionOutputs, nil
+}
+
+// EncodeMultimodal decodes the incoming image and, when Pan & Scan tiling
+// applies, crops it into overlapping tiles at the model's native resolution.
+// The full (downsampled) image and every tile are each run independently
+// through the vision tower and projector; PostTokenize is responsible for
+// wrapping every resulting set of 256 soft tokens with its own
+// <start_of_image>/<end_of_image> markers.
+func (m *Model) EncodeMultimodal(ctx ml.Context, multimodalData []byte) (any, error) {
+	reqID := requestID(ctx)
+
+	img, _, err := image.Decode(bytes.NewReader(multimodalData))
+	if err != nil {
+		return nil, err
+	}
+	m.Events.Publish(reqID, model.EventImageDecoded, img.Bounds())
+
+	tiles := append([]image.Image{img}, m.ImageProcessor.panAndScanCrops(img)...)
+
+	visionOutputs := make([]ml.Tensor, len(tiles))
+	for i, tile := range tiles {
+		visionOutputs[i], err = m.encodeTile(ctx, reqID, tile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return visionOutputs, nil
 }
 
+// flattenTiles expands each image input whose Multimodal holds the
+// per-tile tensors produced by Pan & Scan into one input per tile, so that
+// every tile is wrapped with its own <start_of_image>/<end_of_image>
+// markers further down in PostTokenize. Each tile's hash is derived from
+// the source image's hash so tiles of the same image stay distinguishable.
+func flattenTiles(inputs []input.Input) []input.Input {
+	var flattened []input.Input
+	fnvHash := fnv.New64a()
+
+	for _, inp := range inputs {
+		tiles, ok := inp.Multimodal.([]ml.Tensor)
+		if !ok {
+			flattened = append(flattened, inp)
+			continue
+		}
+
+		for i, tile := range tiles {
+			tileInput := inp
+			tileInput.Multimodal = tile
+
+			fnvHash.Reset()
+			binary.Write(fnvHash, binary.NativeEndian, inp.MultimodalHash)
+			binary.Write(fnvHash, binary.NativeEndian, int64(i))
+			tileInput.MultimodalHash = fnvHash.Sum64()
+
+			flattened = append(flattened, tileInput)
+		}
+	}
+
+	return flattened
+}
+
 func (m *Model) PostTokenize(ctx ml.Context, inputs []input.Input) ([]input.Input, error) {
+	inputs = flattenTiles(inputs)
+
 	var images []input.Input
 	fnvHash := fnv.New64a()
 
@@ -141,6 +259,7 @@ func (m *Model) PostTokenize(ctx ml.Context, inputs []input.Input) ([]input.Inpu
 		}
 	}
 
+	m.Events.Publish(requestID(ctx), model.EventTokensExpanded, len(inputs))
 	return inputs, nil
 }
 
@@ -160,6 +279,7 @@ func (m *Model) Forward(ctx ml.Context, opts input.Options) (ml.Tensor, error) {
 		return nil, err
 	}
 
+	m.Events.Publish(requestID(ctx), model.EventTextForwardStep, len(opts.Inputs))
 	return m.TextModel.Forward(ctx, inputs, positions, outputs, opts.Multimodal, m.Cache), nil
 }
 
@@ -0,0 +1,149 @@
+package gemma3
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+
+	"github.com/ollama/ollama/ml"
+)
+
+type ImageProcessor struct {
+	imageSize   int
+	patchSize   int
+	numChannels int
+
+	panAndScanMinCropSize int
+	panAndScanMaxNumCrops int
+}
+
+func newImageProcessor(c ml.Config) ImageProcessor {
+	return ImageProcessor{
+		imageSize:   int(c.Uint("vision.image_size")),
+		patchSize:   int(c.Uint("vision.patch_size")),
+		numChannels: int(c.Uint("vision.num_channels")),
+
+		panAndScanMinCropSize: int(c.Uint("vision.pan_and_scan_min_crop_size", 256)),
+		panAndScanMaxNumCrops: int(c.Uint("vision.pan_and_scan_max_num_crops", 0)),
+	}
+}
+
+// resize scales img to a square imageSize x imageSize image using bilinear
+// interpolation, matching the preprocessing the vision tower was trained on.
+func (p ImageProcessor) resize(img image.Image) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, p.imageSize, p.imageSize))
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// ProcessImage resizes img and normalizes it into a CHW-ordered float32 slice
+// suitable for VisionModel.Forward.
+func (p ImageProcessor) ProcessImage(img image.Image) ([]float32, error) {
+	img = p.resize(img)
+	bounds := img.Bounds()
+
+	f32s := make([]float32, p.numChannels*p.imageSize*p.imageSize)
+	plane := p.imageSize * p.imageSize
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			i := (y-bounds.Min.Y)*p.imageSize + (x - bounds.Min.X)
+			f32s[0*plane+i] = (float32(r>>8)/255 - 0.5) / 0.5
+			f32s[1*plane+i] = (float32(g>>8)/255 - 0.5) / 0.5
+			f32s[2*plane+i] = (float32(b>>8)/255 - 0.5) / 0.5
+		}
+	}
+
+	return f32s, nil
+}
+
+// gridDim returns how many native-resolution (imageSize) tiles fit along
+// one axis of length dim, so that tiling is driven by absolute resolution
+// rather than aspect ratio alone - a large square image gets its own
+// gridDim x gridDim grid, not just wide/tall images. Clamped to 3, the
+// largest single-axis grid the Pan & Scan spec calls for (up to 3x3).
+func gridDim(dim, imageSize int) int {
+	n := dim / imageSize
+	if n < 1 {
+		n = 1
+	}
+	if n > 3 {
+		n = 3
+	}
+	return n
+}
+
+// panAndScanCrops splits img into an overlapping grid of tiles when its
+// resolution warrants it, following the Pan & Scan scheme: an image large
+// enough, along either or both axes, to hold more than one native-resolution
+// tile is cropped into a gridWidth x gridHeight arrangement of tiles (up to
+// 3x3) in addition to the single downsampled view of the whole image. It
+// returns nil if tiling does not apply.
+func (p ImageProcessor) panAndScanCrops(img image.Image) []image.Image {
+	if p.panAndScanMaxNumCrops <= 0 {
+		return nil
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w < p.panAndScanMinCropSize || h < p.panAndScanMinCropSize {
+		return nil
+	}
+
+	gridW, gridH := gridDim(w, p.imageSize), gridDim(h, p.imageSize)
+
+	for gridW*gridH > p.panAndScanMaxNumCrops {
+		switch {
+		case gridW >= gridH && gridW > 1:
+			gridW--
+		case gridH > 1:
+			gridH--
+		default:
+			return nil
+		}
+	}
+
+	if gridW == 1 && gridH == 1 {
+		return nil
+	}
+
+	tileW, tileH := w/gridW, h/gridH
+
+	// Grow each tile by an overlap margin on every internal edge so
+	// neighboring tiles share a border region, per the Pan & Scan spec.
+	overlapW, overlapH := tileW/10, tileH/10
+
+	var crops []image.Image
+	for gy := 0; gy < gridH; gy++ {
+		for gx := 0; gx < gridW; gx++ {
+			x0 := bounds.Min.X + gx*tileW
+			y0 := bounds.Min.Y + gy*tileH
+			x1, y1 := x0+tileW, y0+tileH
+			if gx == gridW-1 {
+				x1 = bounds.Max.X
+			}
+			if gy == gridH-1 {
+				y1 = bounds.Max.Y
+			}
+
+			if gx > 0 {
+				x0 = max(bounds.Min.X, x0-overlapW)
+			}
+			if gx < gridW-1 {
+				x1 = min(bounds.Max.X, x1+overlapW)
+			}
+			if gy > 0 {
+				y0 = max(bounds.Min.Y, y0-overlapH)
+			}
+			if gy < gridH-1 {
+				y1 = min(bounds.Max.Y, y1+overlapH)
+			}
+
+			crop := image.NewRGBA(image.Rect(0, 0, x1-x0, y1-y0))
+			draw.Draw(crop, crop.Bounds(), img, image.Point{X: x0, Y: y0}, draw.Src)
+			crops = append(crops, crop)
+		}
+	}
+
+	return crops
+}
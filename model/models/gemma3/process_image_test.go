@@ -0,0 +1,83 @@
+package gemma3
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPanAndScanCropsDisabled(t *testing.T) {
+	p := ImageProcessor{imageSize: 256, panAndScanMinCropSize: 256, panAndScanMaxNumCrops: 0}
+	img := image.NewRGBA(image.Rect(0, 0, 1024, 512))
+
+	if crops := p.panAndScanCrops(img); crops != nil {
+		t.Fatalf("expected no crops with panAndScanMaxNumCrops=0, got %d", len(crops))
+	}
+}
+
+func TestPanAndScanCropsTooSmall(t *testing.T) {
+	p := ImageProcessor{imageSize: 256, panAndScanMinCropSize: 256, panAndScanMaxNumCrops: 4}
+	img := image.NewRGBA(image.Rect(0, 0, 128, 128))
+
+	if crops := p.panAndScanCrops(img); crops != nil {
+		t.Fatalf("expected no crops for an image below panAndScanMinCropSize, got %d", len(crops))
+	}
+}
+
+func TestPanAndScanCropsWideImage(t *testing.T) {
+	p := ImageProcessor{imageSize: 256, panAndScanMinCropSize: 256, panAndScanMaxNumCrops: 4}
+	img := image.NewRGBA(image.Rect(0, 0, 1024, 512))
+
+	crops := p.panAndScanCrops(img)
+	if len(crops) != 4 {
+		t.Fatalf("expected a 2x2 grid (w fits 4x, h fits 2x imageSize, capped to 4 crops), got %d crops", len(crops))
+	}
+}
+
+func TestPanAndScanCropsSquareImageTiles(t *testing.T) {
+	// A large square image never hit either aspect-ratio branch in the old
+	// implementation and was never tiled; size-driven gridDim must tile it.
+	p := ImageProcessor{imageSize: 1024, panAndScanMinCropSize: 1024, panAndScanMaxNumCrops: 9}
+	img := image.NewRGBA(image.Rect(0, 0, 3072, 3072))
+
+	crops := p.panAndScanCrops(img)
+	if len(crops) != 9 {
+		t.Fatalf("expected a 3x3 grid for a 3x native-resolution square image, got %d crops", len(crops))
+	}
+}
+
+func TestPanAndScanCropsOverlap(t *testing.T) {
+	p := ImageProcessor{imageSize: 256, panAndScanMinCropSize: 256, panAndScanMaxNumCrops: 4}
+	img := image.NewRGBA(image.Rect(0, 0, 1024, 512))
+
+	for _, c := range p.panAndScanCrops(img) {
+		if c.Bounds().Dx() <= 1024/2 || c.Bounds().Dy() <= 512/2 {
+			t.Errorf("expected crop larger than its exact grid share to reflect overlap, got %v", c.Bounds())
+		}
+	}
+}
+
+func TestPanAndScanCropsRespectsMaxNumCrops(t *testing.T) {
+	p := ImageProcessor{imageSize: 256, panAndScanMinCropSize: 256, panAndScanMaxNumCrops: 1}
+	img := image.NewRGBA(image.Rect(0, 0, 1024, 512))
+
+	if crops := p.panAndScanCrops(img); crops != nil {
+		t.Fatalf("expected tiling to back off to a single view when maxNumCrops=1, got %d crops", len(crops))
+	}
+}
+
+func TestGridDim(t *testing.T) {
+	cases := []struct {
+		dim, imageSize, want int
+	}{
+		{100, 256, 1},
+		{256, 256, 1},
+		{512, 256, 2},
+		{4096, 256, 3}, // clamped
+	}
+
+	for _, c := range cases {
+		if got := gridDim(c.dim, c.imageSize); got != c.want {
+			t.Errorf("gridDim(%d, %d) = %d, want %d", c.dim, c.imageSize, got, c.want)
+		}
+	}
+}